@@ -0,0 +1,82 @@
+// Package alert 实现告警监控：周期性地对照 dataDir/alerts.yaml 里配置的规则
+// 评估最新数据，触发时通过 SMTP 和/或 Webhook 发出通知。
+package alert
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rule 描述一条告警规则，Type 决定下面哪些字段生效：
+//   - error_burst：最近 WindowMinutes 分钟内 ERROR 条数 > Threshold
+//   - unknown_spike：最近 WindowMinutes 分钟内 UNKNOWN 匹配次数 > Threshold
+//   - silence：WorkingHours 时段内超过 WindowMinutes 分钟没有 MATCH 记录
+type Rule struct {
+	Name            string `yaml:"name"`
+	Type            string `yaml:"type"`
+	WindowMinutes   int    `yaml:"window_minutes"`
+	Threshold       int    `yaml:"threshold"`
+	CooldownMinutes int    `yaml:"cooldown_minutes"`
+	WorkingHours    string `yaml:"working_hours"` // 如 "09:00-18:00"，留空表示不限时段
+}
+
+func (r Rule) window() time.Duration {
+	if r.WindowMinutes <= 0 {
+		return 10 * time.Minute
+	}
+	return time.Duration(r.WindowMinutes) * time.Minute
+}
+
+func (r Rule) cooldown() time.Duration {
+	if r.CooldownMinutes <= 0 {
+		return 30 * time.Minute
+	}
+	return time.Duration(r.CooldownMinutes) * time.Minute
+}
+
+// SMTPConfig 只放非敏感字段；账号密码固定从环境变量 ALERT_SMTP_USERNAME /
+// ALERT_SMTP_PASSWORD 读取，不落到 alerts.yaml 里。
+type SMTPConfig struct {
+	Host string   `yaml:"host"`
+	Port int      `yaml:"port"`
+	From string   `yaml:"from"`
+	To   []string `yaml:"to"`
+}
+
+// WebhookConfig 中 Kind 决定 payload 的形状，取值 slack|feishu|dingtalk，默认 slack。
+type WebhookConfig struct {
+	URL  string `yaml:"url"`
+	Kind string `yaml:"kind"`
+}
+
+type NotifyConfig struct {
+	SMTP    *SMTPConfig    `yaml:"smtp"`
+	Webhook *WebhookConfig `yaml:"webhook"`
+}
+
+// Config 是 alerts.yaml 的顶层结构。
+type Config struct {
+	Rules  []Rule       `yaml:"rules"`
+	Notify NotifyConfig `yaml:"notify"`
+}
+
+// LoadConfig 读取并解析 alerts.yaml。文件不存在时返回空配置（没有规则），
+// 方便不需要告警的部署直接跳过，不必单独加开关。
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Config{}, nil
+		}
+		return nil, fmt.Errorf("读取 %s 失败: %w", path, err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %w", path, err)
+	}
+	return &cfg, nil
+}
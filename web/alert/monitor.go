@@ -0,0 +1,211 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Store 是 Monitor 评估规则所需的最小只读接口，由 store.Store 的同名方法满足；
+// 用结构化接口避免 alert 包反向依赖 store 包。
+type Store interface {
+	CountSince(status string, since time.Time) (int64, error)
+	CountUnknownMatches(since time.Time) (int64, error)
+	LastMatchAt() (t time.Time, ok bool, err error)
+}
+
+// Monitor 周期性地对照 alerts.yaml 里的规则评估 Store 的最新数据，
+// 每条规则按名字 + 冷却窗口去重，避免持续故障反复刷屏通知渠道。
+type Monitor struct {
+	store   Store
+	cfgPath string
+	onError func(error)
+
+	mu        sync.Mutex
+	cfg       *Config
+	notifiers []Notifier
+	lastFired map[string]time.Time
+}
+
+// NewMonitor 创建一个监控器；onError 在规则评估或通知发送失败时被调用（可为空）。
+func NewMonitor(st Store, cfgPath string, onError func(error)) *Monitor {
+	return &Monitor{
+		store:     st,
+		cfgPath:   cfgPath,
+		onError:   onError,
+		lastFired: make(map[string]time.Time),
+	}
+}
+
+// Reload 重新读取 alerts.yaml 并重建通知渠道；可在启动时调用一次，
+// 之后每次收到 SIGHUP 再调用一次做热加载。
+func (m *Monitor) Reload() error {
+	cfg, err := LoadConfig(m.cfgPath)
+	if err != nil {
+		return err
+	}
+
+	var notifiers []Notifier
+	if cfg.Notify.SMTP != nil {
+		notifiers = append(notifiers, newSMTPNotifier(*cfg.Notify.SMTP))
+	}
+	if cfg.Notify.Webhook != nil {
+		notifiers = append(notifiers, newWebhookNotifier(*cfg.Notify.Webhook))
+	}
+
+	m.mu.Lock()
+	m.cfg = cfg
+	m.notifiers = notifiers
+	m.mu.Unlock()
+	return nil
+}
+
+// Run 每隔 interval 评估一次规则，直到 ctx 被取消为止。
+func (m *Monitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.evaluate()
+		}
+	}
+}
+
+func (m *Monitor) evaluate() {
+	m.mu.Lock()
+	cfg := m.cfg
+	notifiers := m.notifiers
+	m.mu.Unlock()
+	if cfg == nil {
+		return
+	}
+
+	for _, rule := range cfg.Rules {
+		triggered, detail, err := m.check(rule)
+		if err != nil {
+			m.reportError(fmt.Errorf("规则 %s 评估失败: %w", rule.Name, err))
+			continue
+		}
+		if !triggered || !m.shouldFire(rule) {
+			continue
+		}
+		m.fire(rule, detail, notifiers)
+	}
+}
+
+func (m *Monitor) check(rule Rule) (triggered bool, detail string, err error) {
+	// store.parseTimestamp 把 records.csv 里不带时区的时间戳一律按 UTC 解析，
+	// 窗口计算必须用同一时区的 now，否则在非 UTC 主机上整个窗口会偏移时区差。
+	now := time.Now().UTC()
+
+	switch rule.Type {
+	case "error_burst":
+		n, err := m.store.CountSince("ERROR", now.Add(-rule.window()))
+		if err != nil {
+			return false, "", err
+		}
+		if int(n) > rule.Threshold {
+			return true, fmt.Sprintf("最近 %d 分钟内出现 %d 条 ERROR（阈值 %d）", rule.WindowMinutes, n, rule.Threshold), nil
+		}
+		return false, "", nil
+
+	case "unknown_spike":
+		n, err := m.store.CountUnknownMatches(now.Add(-rule.window()))
+		if err != nil {
+			return false, "", err
+		}
+		if int(n) > rule.Threshold {
+			return true, fmt.Sprintf("最近 %d 分钟内出现 %d 次 UNKNOWN 匹配（阈值 %d）", rule.WindowMinutes, n, rule.Threshold), nil
+		}
+		return false, "", nil
+
+	case "silence":
+		// 工作时间是运行设备上的当地作息，按本地墙钟判断；不受上面 UTC 换算影响。
+		if !withinWorkingHours(rule.WorkingHours, time.Now()) {
+			return false, "", nil
+		}
+		last, ok, err := m.store.LastMatchAt()
+		if err != nil {
+			return false, "", err
+		}
+		if !ok {
+			return true, "从未出现过 MATCH 记录", nil
+		}
+		if d := now.Sub(last); d > rule.window() {
+			return true, fmt.Sprintf("已有 %s 没有 MATCH 记录（阈值 %s）", d.Round(time.Minute), rule.window()), nil
+		}
+		return false, "", nil
+
+	default:
+		return false, "", fmt.Errorf("未知的规则类型: %s", rule.Type)
+	}
+}
+
+// shouldFire 实现冷却窗口去重：同一条规则在冷却期内只触发一次。
+func (m *Monitor) shouldFire(rule Rule) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if last, ok := m.lastFired[rule.Name]; ok && now.Sub(last) < rule.cooldown() {
+		return false
+	}
+	m.lastFired[rule.Name] = now
+	return true
+}
+
+func (m *Monitor) fire(rule Rule, detail string, notifiers []Notifier) {
+	subject := fmt.Sprintf("[pi-face 告警] %s", rule.Name)
+	for _, n := range notifiers {
+		if err := n.Notify(subject, detail); err != nil {
+			m.reportError(fmt.Errorf("规则 %s 通知失败: %w", rule.Name, err))
+		}
+	}
+}
+
+func (m *Monitor) reportError(err error) {
+	if m.onError != nil {
+		m.onError(err)
+	}
+}
+
+// withinWorkingHours 判断 now 是否落在形如 "09:00-18:00" 的时间段内；
+// spec 为空表示不限制（全天都算工作时间）。
+func withinWorkingHours(spec string, now time.Time) bool {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return true
+	}
+
+	parts := strings.SplitN(spec, "-", 2)
+	if len(parts) != 2 {
+		return true
+	}
+	start, okStart := parseClock(parts[0])
+	end, okEnd := parseClock(parts[1])
+	if !okStart || !okEnd {
+		return true
+	}
+
+	cur := now.Hour()*60 + now.Minute()
+	return cur >= start && cur <= end
+}
+
+func parseClock(s string) (int, bool) {
+	hm := strings.SplitN(strings.TrimSpace(s), ":", 2)
+	if len(hm) != 2 {
+		return 0, false
+	}
+	h, err1 := strconv.Atoi(hm[0])
+	mnt, err2 := strconv.Atoi(hm[1])
+	if err1 != nil || err2 != nil {
+		return 0, false
+	}
+	return h*60 + mnt, true
+}
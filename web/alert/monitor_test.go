@@ -0,0 +1,122 @@
+package alert
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeStore 是只返回预设值的 Store 实现，供规则评估测试使用。
+type fakeStore struct {
+	errorCount   int64
+	unknownCount int64
+	lastMatch    time.Time
+	hasMatch     bool
+}
+
+func (f *fakeStore) CountSince(status string, since time.Time) (int64, error) {
+	return f.errorCount, nil
+}
+
+func (f *fakeStore) CountUnknownMatches(since time.Time) (int64, error) {
+	return f.unknownCount, nil
+}
+
+func (f *fakeStore) LastMatchAt() (time.Time, bool, error) {
+	return f.lastMatch, f.hasMatch, nil
+}
+
+// TestCheck_ErrorBurst_ThresholdBoundary 验证阈值是“严格大于”，恰好等于阈值不触发，
+// 符合 check() 里 `int(n) > rule.Threshold` 的判断。
+func TestCheck_ErrorBurst_ThresholdBoundary(t *testing.T) {
+	rule := Rule{Name: "errors", Type: "error_burst", WindowMinutes: 5, Threshold: 3}
+
+	m := NewMonitor(&fakeStore{errorCount: 3}, "", nil)
+	triggered, _, err := m.check(rule)
+	if err != nil {
+		t.Fatalf("check 失败: %v", err)
+	}
+	if triggered {
+		t.Errorf("count == threshold (3) 不应触发")
+	}
+
+	m = NewMonitor(&fakeStore{errorCount: 4}, "", nil)
+	triggered, detail, err := m.check(rule)
+	if err != nil {
+		t.Fatalf("check 失败: %v", err)
+	}
+	if !triggered {
+		t.Errorf("count > threshold (4 > 3) 应触发")
+	}
+	if detail == "" {
+		t.Errorf("触发时 detail 不应为空")
+	}
+}
+
+func TestCheck_UnknownSpike(t *testing.T) {
+	rule := Rule{Name: "unknowns", Type: "unknown_spike", WindowMinutes: 10, Threshold: 2}
+
+	m := NewMonitor(&fakeStore{unknownCount: 2}, "", nil)
+	if triggered, _, _ := m.check(rule); triggered {
+		t.Errorf("count == threshold (2) 不应触发")
+	}
+
+	m = NewMonitor(&fakeStore{unknownCount: 5}, "", nil)
+	if triggered, _, _ := m.check(rule); !triggered {
+		t.Errorf("count > threshold (5 > 2) 应触发")
+	}
+}
+
+// TestCheck_Silence_NoWorkingHoursRestriction 验证 WorkingHours 留空时不限制时段，
+// 纯按“距最近一次 MATCH 的时长是否超过窗口”判断。
+func TestCheck_Silence_NoWorkingHoursRestriction(t *testing.T) {
+	rule := Rule{Name: "silence", Type: "silence", WindowMinutes: 60}
+
+	m := NewMonitor(&fakeStore{hasMatch: false}, "", nil)
+	triggered, _, err := m.check(rule)
+	if err != nil {
+		t.Fatalf("check 失败: %v", err)
+	}
+	if !triggered {
+		t.Errorf("从未出现过 MATCH 时应触发")
+	}
+
+	m = NewMonitor(&fakeStore{hasMatch: true, lastMatch: time.Now().UTC().Add(-30 * time.Minute)}, "", nil)
+	if triggered, _, _ := m.check(rule); triggered {
+		t.Errorf("30 分钟前有 MATCH，未超过 60 分钟窗口，不应触发")
+	}
+
+	m = NewMonitor(&fakeStore{hasMatch: true, lastMatch: time.Now().UTC().Add(-90 * time.Minute)}, "", nil)
+	if triggered, _, _ := m.check(rule); !triggered {
+		t.Errorf("90 分钟前有 MATCH，超过 60 分钟窗口，应触发")
+	}
+}
+
+func TestCheck_UnknownRuleType(t *testing.T) {
+	m := NewMonitor(&fakeStore{}, "", nil)
+	if _, _, err := m.check(Rule{Name: "bogus", Type: "bogus"}); err == nil {
+		t.Errorf("未知规则类型应返回错误")
+	}
+}
+
+// TestShouldFire_CooldownDedup 验证同一条规则在冷却窗口内只触发一次，
+// 避免持续故障反复发送通知。
+func TestShouldFire_CooldownDedup(t *testing.T) {
+	m := NewMonitor(&fakeStore{}, "", nil)
+	rule := Rule{Name: "errors", CooldownMinutes: 30}
+
+	if !m.shouldFire(rule) {
+		t.Fatalf("第一次触发应放行")
+	}
+	if m.shouldFire(rule) {
+		t.Fatalf("冷却窗口内的第二次触发应被去重")
+	}
+
+	// 模拟冷却窗口已过期：把 lastFired 提前到超过 cooldown 之前。
+	m.mu.Lock()
+	m.lastFired[rule.Name] = time.Now().Add(-31 * time.Minute)
+	m.mu.Unlock()
+
+	if !m.shouldFire(rule) {
+		t.Errorf("冷却窗口过期后应重新放行")
+	}
+}
@@ -0,0 +1,99 @@
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"gopkg.in/gomail.v2"
+)
+
+// Notifier 把一条告警文案发出去；SMTP 和 Webhook 各有一份实现。
+type Notifier interface {
+	Notify(subject, body string) error
+}
+
+type smtpNotifier struct {
+	cfg  SMTPConfig
+	user string
+	pass string
+}
+
+func newSMTPNotifier(cfg SMTPConfig) Notifier {
+	return &smtpNotifier{
+		cfg:  cfg,
+		user: os.Getenv("ALERT_SMTP_USERNAME"),
+		pass: os.Getenv("ALERT_SMTP_PASSWORD"),
+	}
+}
+
+func (n *smtpNotifier) Notify(subject, body string) error {
+	if len(n.cfg.To) == 0 {
+		return fmt.Errorf("smtp 通知未配置收件人")
+	}
+
+	m := gomail.NewMessage()
+	m.SetHeader("From", n.cfg.From)
+	m.SetHeader("To", n.cfg.To...)
+	m.SetHeader("Subject", subject)
+	m.SetBody("text/plain", body)
+
+	d := gomail.NewDialer(n.cfg.Host, n.cfg.Port, n.user, n.pass)
+	if err := d.DialAndSend(m); err != nil {
+		return fmt.Errorf("发送告警邮件失败: %w", err)
+	}
+	return nil
+}
+
+type webhookNotifier struct {
+	url    string
+	kind   string
+	client *http.Client
+}
+
+func newWebhookNotifier(cfg WebhookConfig) Notifier {
+	return &webhookNotifier{
+		url:    cfg.URL,
+		kind:   cfg.Kind,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (n *webhookNotifier) Notify(subject, body string) error {
+	msg := subject + "\n" + body
+
+	var payload any
+	switch n.kind {
+	case "feishu":
+		payload = map[string]any{
+			"msg_type": "text",
+			"content":  map[string]string{"text": msg},
+		}
+	case "dingtalk":
+		payload = map[string]any{
+			"msgtype": "text",
+			"text":    map[string]string{"content": msg},
+		}
+	default: // slack 及兼容 Slack incoming webhook 格式的服务
+		payload = map[string]string{"text": msg}
+	}
+
+	buf, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("编码 webhook payload 失败: %w", err)
+	}
+
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(buf))
+	if err != nil {
+		return fmt.Errorf("发送 webhook 失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook 返回状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
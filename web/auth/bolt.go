@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+var sessionsBucket = []byte("sessions")
+
+type boltStore struct {
+	db *bbolt.DB
+}
+
+// NewBoltStore 打开（必要时创建）一个 BoltDB 文件作为会话存储，
+// 供需要重启后保留登录态的部署场景使用；一般场景用 NewMemoryStore 即可。
+func NewBoltStore(path string) (Store, error) {
+	db, err := bbolt.Open(path, 0o600, &bbolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("打开会话数据库 %s 失败: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(sessionsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("初始化会话数据库失败: %w", err)
+	}
+
+	return &boltStore{db: db}, nil
+}
+
+func (b *boltStore) Create(sess Session) error {
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		data, err := json.Marshal(sess)
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(sessionsBucket).Put([]byte(sess.Token), data)
+	})
+}
+
+func (b *boltStore) Get(token string) (Session, bool, error) {
+	var sess Session
+	var found bool
+
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		data := tx.Bucket(sessionsBucket).Get([]byte(token))
+		if data == nil {
+			return nil
+		}
+		if err := json.Unmarshal(data, &sess); err != nil {
+			return err
+		}
+		found = true
+		return nil
+	})
+	if err != nil {
+		return Session{}, false, err
+	}
+	if !found {
+		return Session{}, false, nil
+	}
+	if sess.expired(time.Now()) {
+		_ = b.Delete(token)
+		return Session{}, false, nil
+	}
+	return sess, true, nil
+}
+
+func (b *boltStore) Refresh(token string, ttl time.Duration) error {
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(sessionsBucket)
+		data := bucket.Get([]byte(token))
+		if data == nil {
+			return nil
+		}
+		var sess Session
+		if err := json.Unmarshal(data, &sess); err != nil {
+			return err
+		}
+		sess.ExpiresAt = time.Now().Add(ttl)
+		updated, err := json.Marshal(sess)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(token), updated)
+	})
+}
+
+func (b *boltStore) Delete(token string) error {
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(sessionsBucket).Delete([]byte(token))
+	})
+}
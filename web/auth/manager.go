@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"errors"
+	"time"
+)
+
+// CookieName 是携带会话 token 的 cookie 名称。
+const CookieName = "piface_session"
+
+// DefaultTTL 是会话的默认有效期，每次通过 Authenticate 校验成功都会滑动续期。
+const DefaultTTL = 24 * time.Hour
+
+// ErrInvalidCredentials 在用户名不存在或密码不匹配时返回。
+var ErrInvalidCredentials = errors.New("用户名或密码错误")
+
+// Manager 把用户表、会话存储和过期时间粘在一起，对外提供登录/鉴权/登出三个动作。
+type Manager struct {
+	users    map[string]User
+	sessions Store
+	ttl      time.Duration
+}
+
+// NewManager 创建一个 Manager。ttl <= 0 时使用 DefaultTTL。
+func NewManager(users map[string]User, sessions Store, ttl time.Duration) *Manager {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Manager{users: users, sessions: sessions, ttl: ttl}
+}
+
+// Login 校验用户名密码，成功后签发并持久化一个新会话。
+func (m *Manager) Login(username, password string) (Session, error) {
+	user, ok := m.users[username]
+	if !ok || !CheckPassword(user.PasswordHash, password) {
+		return Session{}, ErrInvalidCredentials
+	}
+
+	token, err := NewToken()
+	if err != nil {
+		return Session{}, err
+	}
+
+	sess := Session{
+		Token:     token,
+		Username:  user.Username,
+		Role:      user.Role,
+		ExpiresAt: time.Now().Add(m.ttl),
+	}
+	if err := m.sessions.Create(sess); err != nil {
+		return Session{}, err
+	}
+	return sess, nil
+}
+
+// Authenticate 校验 token 是否对应一个有效会话，成功时顺带把过期时间往后滑动。
+func (m *Manager) Authenticate(token string) (Session, bool) {
+	if token == "" {
+		return Session{}, false
+	}
+	sess, ok, err := m.sessions.Get(token)
+	if err != nil || !ok {
+		return Session{}, false
+	}
+	_ = m.sessions.Refresh(token, m.ttl)
+	return sess, true
+}
+
+// Logout 使一个会话失效。
+func (m *Manager) Logout(token string) error {
+	return m.sessions.Delete(token)
+}
@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func mustHash(t *testing.T, password string) string {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("生成密码哈希失败: %v", err)
+	}
+	return string(hash)
+}
+
+func newTestManager(t *testing.T, ttl time.Duration) *Manager {
+	t.Helper()
+	users := map[string]User{
+		"admin": {Username: "admin", PasswordHash: mustHash(t, "s3cret"), Role: "admin"},
+	}
+	return NewManager(users, NewMemoryStore(), ttl)
+}
+
+func TestLogin_Success(t *testing.T) {
+	m := newTestManager(t, time.Hour)
+
+	sess, err := m.Login("admin", "s3cret")
+	if err != nil {
+		t.Fatalf("Login 失败: %v", err)
+	}
+	if sess.Token == "" {
+		t.Errorf("Token 不应为空")
+	}
+	if sess.Username != "admin" || sess.Role != "admin" {
+		t.Errorf("Session = %+v, 期望 Username=admin Role=admin", sess)
+	}
+}
+
+func TestLogin_BadPassword(t *testing.T) {
+	m := newTestManager(t, time.Hour)
+
+	if _, err := m.Login("admin", "wrong"); err != ErrInvalidCredentials {
+		t.Fatalf("Login(错误密码) 返回 %v, 期望 ErrInvalidCredentials", err)
+	}
+}
+
+func TestLogin_UnknownUser(t *testing.T) {
+	m := newTestManager(t, time.Hour)
+
+	if _, err := m.Login("nobody", "whatever"); err != ErrInvalidCredentials {
+		t.Fatalf("Login(不存在的用户) 返回 %v, 期望 ErrInvalidCredentials", err)
+	}
+}
+
+func TestAuthenticate_ValidSession(t *testing.T) {
+	m := newTestManager(t, time.Hour)
+	sess, err := m.Login("admin", "s3cret")
+	if err != nil {
+		t.Fatalf("Login 失败: %v", err)
+	}
+
+	got, ok := m.Authenticate(sess.Token)
+	if !ok {
+		t.Fatalf("Authenticate(有效 token) 应成功")
+	}
+	if got.Username != "admin" {
+		t.Errorf("Authenticate 返回 Username = %q, 期望 admin", got.Username)
+	}
+}
+
+func TestAuthenticate_EmptyOrUnknownToken(t *testing.T) {
+	m := newTestManager(t, time.Hour)
+
+	if _, ok := m.Authenticate(""); ok {
+		t.Errorf("Authenticate(空 token) 不应成功")
+	}
+	if _, ok := m.Authenticate("not-a-real-token"); ok {
+		t.Errorf("Authenticate(未知 token) 不应成功")
+	}
+}
+
+func TestAuthenticate_ExpiredSessionRejected(t *testing.T) {
+	m := newTestManager(t, time.Hour)
+	sess, err := m.Login("admin", "s3cret")
+	if err != nil {
+		t.Fatalf("Login 失败: %v", err)
+	}
+
+	// 直接篡改底层存储里的过期时间，模拟 TTL 已耗尽，而不是真的等待一小时。
+	setSessionExpiresAt(t, m, sess.Token, time.Now().Add(-time.Minute))
+
+	if _, ok := m.Authenticate(sess.Token); ok {
+		t.Fatalf("Authenticate(已过期会话) 不应成功")
+	}
+}
+
+func TestAuthenticate_SlidingRefresh(t *testing.T) {
+	m := newTestManager(t, time.Hour)
+	sess, err := m.Login("admin", "s3cret")
+	if err != nil {
+		t.Fatalf("Login 失败: %v", err)
+	}
+
+	// 把过期时间提前到 1 分钟后：如果 Authenticate 没有顺带续期，
+	// ExpiresAt 会停在约 1 分钟后，达不到下面检查的 30 分钟下限。
+	setSessionExpiresAt(t, m, sess.Token, time.Now().Add(time.Minute))
+	if _, ok := m.Authenticate(sess.Token); !ok {
+		t.Fatalf("Authenticate 首次调用应成功")
+	}
+
+	refreshed, ok, err := m.sessions.Get(sess.Token)
+	if err != nil || !ok {
+		t.Fatalf("续期后读取会话失败: ok=%v err=%v", ok, err)
+	}
+	if !refreshed.ExpiresAt.After(time.Now().Add(30 * time.Minute)) {
+		t.Errorf("Authenticate 应把过期时间滑动续期到约 ttl 之后，实际 ExpiresAt=%v", refreshed.ExpiresAt)
+	}
+}
+
+// setSessionExpiresAt 直接改写内存会话存储里的过期时间，避免测试真的等待 TTL 流逝。
+func setSessionExpiresAt(t *testing.T, m *Manager, token string, expiresAt time.Time) {
+	t.Helper()
+	ms, ok := m.sessions.(*memoryStore)
+	if !ok {
+		t.Fatalf("期望 Manager 使用 memoryStore")
+	}
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	sess, ok := ms.sessions[token]
+	if !ok {
+		t.Fatalf("会话 %s 不存在", token)
+	}
+	sess.ExpiresAt = expiresAt
+	ms.sessions[token] = sess
+}
+
+func TestLogout(t *testing.T) {
+	m := newTestManager(t, time.Hour)
+	sess, err := m.Login("admin", "s3cret")
+	if err != nil {
+		t.Fatalf("Login 失败: %v", err)
+	}
+
+	if err := m.Logout(sess.Token); err != nil {
+		t.Fatalf("Logout 失败: %v", err)
+	}
+	if _, ok := m.Authenticate(sess.Token); ok {
+		t.Fatalf("Logout 之后该 token 不应再通过 Authenticate")
+	}
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// Session 是一次登录产生的会话；有效请求会顺带把 ExpiresAt 往后滑动（sliding TTL）。
+type Session struct {
+	Token     string
+	Username  string
+	Role      string
+	ExpiresAt time.Time
+}
+
+func (s Session) expired(now time.Time) bool {
+	return now.After(s.ExpiresAt)
+}
+
+// Store 管理会话的增删查。NewMemoryStore 够用于单机部署，NewBoltStore 额外提供
+// 重启后会话不丢失的持久化版本。
+type Store interface {
+	Create(sess Session) error
+	Get(token string) (Session, bool, error)
+	Refresh(token string, ttl time.Duration) error
+	Delete(token string) error
+}
+
+type memoryStore struct {
+	mu       sync.Mutex
+	sessions map[string]Session
+}
+
+// NewMemoryStore 创建一个纯内存的会话存储，进程重启后所有会话失效。
+func NewMemoryStore() Store {
+	return &memoryStore{sessions: make(map[string]Session)}
+}
+
+func (m *memoryStore) Create(sess Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[sess.Token] = sess
+	return nil
+}
+
+func (m *memoryStore) Get(token string) (Session, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sess, ok := m.sessions[token]
+	if !ok || sess.expired(time.Now()) {
+		delete(m.sessions, token)
+		return Session{}, false, nil
+	}
+	return sess, true, nil
+}
+
+func (m *memoryStore) Refresh(token string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sess, ok := m.sessions[token]
+	if !ok {
+		return nil
+	}
+	sess.ExpiresAt = time.Now().Add(ttl)
+	m.sessions[token] = sess
+	return nil
+}
+
+func (m *memoryStore) Delete(token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, token)
+	return nil
+}
@@ -0,0 +1,43 @@
+// Package auth 实现登录校验与会话管理：bcrypt 密码校验、32 字节随机会话 token、
+// 内存/BoltDB 两种会话存储，以及登录态的滑动过期。
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// User 对应 users.json 里的一条记录。Role 目前只有 "admin" 和 "viewer" 两种取值，
+// 只有 admin 能访问删除记录、重命名标签这类管理接口。
+type User struct {
+	Username     string `json:"username"`
+	PasswordHash string `json:"password_hash"` // bcrypt
+	Role         string `json:"role"`
+}
+
+// LoadUsers 读取 dataDir/users.json，返回 username -> User 的映射。
+func LoadUsers(path string) (map[string]User, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取 %s 失败: %w", path, err)
+	}
+
+	var users []User
+	if err := json.Unmarshal(data, &users); err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %w", path, err)
+	}
+
+	byName := make(map[string]User, len(users))
+	for _, u := range users {
+		byName[u.Username] = u
+	}
+	return byName, nil
+}
+
+// CheckPassword 校验明文密码是否匹配 bcrypt 哈希。
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
@@ -2,12 +2,13 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net"
 	"net/http"
 	"os"
@@ -18,6 +19,12 @@ import (
 	"strings"
 	"syscall"
 	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"pi-face/alert"
+	"pi-face/auth"
+	"pi-face/store"
 )
 
 // 单条日志记录（严格使用当前 records.csv 格式：无表头、5 列）
@@ -51,20 +58,28 @@ type MonthPersonDays struct {
 	Days   int    `json:"days"`
 }
 
+// RangeEcho 把本次请求实际生效的日期范围回显给前端（闭区间，均为 YYYY-MM-DD）。
+type RangeEcho struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
 // /api/stats 返回体（保持你前端 app.js 当前使用的字段：person_day、all_persons）
 // 其中 person_day 是按人+日去重后的签到记录。
 type StatsResponse struct {
-	Total        int               `json:"total"`             // 原始总记录数
-	MatchRaw     int               `json:"match_raw"`         // status=MATCH 的原始记录数（未去重）
-	Valid        int               `json:"valid"`             // 按天去重后的有效签到次数（同一人同一天算一次）
-	Error        int               `json:"error"`             // status=ERROR
-	NoFace       int               `json:"no_face"`           // status=NO_FACE
-	OtherInvalid int               `json:"other_invalid"`     // 其他非 MATCH 的状态
-	PersonDay    []PersonDayCount  `json:"person_day"`        // 某人某日是否签到（一天只算一次）
-	DayPeople    []DayPeopleCount  `json:"day_people"`        // 某日有几个人来（人数去重）
-	MonthPerson  []MonthPersonDays `json:"month_person_days"` // 某月某人来的天数（天数去重）
-	AllPersons   []string          `json:"all_persons"`       // 所有人员姓名（来自 label_map.json；若读取失败则为空）
-	LabelMap     map[string]string `json:"label_map"`         // ID -> 姓名，对应 label_map.json
+	Total         int               `json:"total"`             // 原始总记录数
+	MatchRaw      int               `json:"match_raw"`         // status=MATCH 的原始记录数（未去重）
+	Valid         int               `json:"valid"`             // 按天去重后的有效签到次数（同一人同一天算一次）
+	Error         int               `json:"error"`             // status=ERROR
+	NoFace        int               `json:"no_face"`           // status=NO_FACE
+	OtherInvalid  int               `json:"other_invalid"`     // 其他非 MATCH 的状态
+	PersonDay     []PersonDayCount  `json:"person_day"`        // 某人某日是否签到（一天只算一次）
+	DayPeople     []DayPeopleCount  `json:"day_people"`        // 某日有几个人来（人数去重）
+	MonthPerson   []MonthPersonDays `json:"month_person_days"` // 某月某人来的天数（天数去重）
+	AllPersons    []string          `json:"all_persons"`       // 所有人员姓名（来自 label_map.json；若读取失败则为空）
+	AbsentPersons []string          `json:"absent_persons"`    // AllPersons 中在本次范围内未出现的人
+	LabelMap      map[string]string `json:"label_map"`         // ID -> 姓名，对应 label_map.json
+	Range         RangeEcho         `json:"range"`             // 本次统计实际生效的日期范围
 }
 
 var (
@@ -72,56 +87,99 @@ var (
 	csvPath      string // 日志 CSV 文件路径（相对路径），默认 dataDir/logs/records.csv
 	labelMapPath string // label_map.json 路径，默认 dataDir/feature_db/label_map.json
 	staticDir    string // 前端静态资源目录，默认 ./static
+
+	db store.Store // 记录持久化层，默认 SQLite，DB_DSN 非空时使用 MySQL
+
+	authManager *auth.Manager // 登录校验与会话管理，users.json 读取失败时为空用户表（所有登录都会被拒绝）
 )
 
 
-// 格式：YYYY-MM-DD HH:MM:SS [INFO] message
-var logFile *os.File
+// 结构化日志：JSON 写入 dataDir/logs/server.jsonl，按大小/时间/份数轮转（lumberjack）。
+// 每条请求日志都带 request_id，方便从日志聚合系统里串起一次请求的完整链路。
+var logger *slog.Logger
 
+type ctxKeyRequestID struct{}
+
+// initLogger 按环境变量配置 lumberjack 轮转参数：
+// LOG_MAX_SIZE_MB（单文件大小，默认 100）、LOG_MAX_AGE_DAYS（保留天数，默认 14）、
+// LOG_MAX_BACKUPS（保留份数，默认 7）、LOG_COMPRESS（是否压缩旧文件，默认 true）。
 func initLogger(dataDir string) (string, error) {
 	logDir := filepath.Join(dataDir, "logs")
 	if err := os.MkdirAll(logDir, 0o755); err != nil {
 		return "", err
 	}
 
-	logPath := filepath.Join(logDir, "2.txt")
-	f, err := os.OpenFile(logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
-	if err != nil {
-		return "", err
+	logPath := filepath.Join(logDir, "server.jsonl")
+	rotator := &lumberjack.Logger{
+		Filename:   logPath,
+		MaxSize:    envIntDefault("LOG_MAX_SIZE_MB", 100),
+		MaxAge:     envIntDefault("LOG_MAX_AGE_DAYS", 14),
+		MaxBackups: envIntDefault("LOG_MAX_BACKUPS", 7),
+		Compress:   envBoolDefault("LOG_COMPRESS", true),
 	}
-	logFile = f
 
-	log.SetFlags(0)
-	log.SetOutput(f)
+	logger = slog.New(slog.NewJSONHandler(rotator, nil))
 	return logPath, nil
 }
 
-func closeLogger() {
-	if logFile != nil {
-		_ = logFile.Close()
+func closeLogger() {}
+
+func logWith(ctx context.Context, level slog.Level, msg string) {
+	if logger == nil {
+		return
+	}
+	if rid, _ := ctx.Value(ctxKeyRequestID{}).(string); rid != "" {
+		logger.Log(ctx, level, msg, slog.String("request_id", rid))
+		return
 	}
+	logger.Log(ctx, level, msg)
 }
 
-func logLine(level, msg string) {
-	ts := time.Now().Format("2006-01-02 15:04:05")
-	log.Printf("%s [%s] %s", ts, level, msg)
-}
+func logInfo(ctx context.Context, msg string)  { logWith(ctx, slog.LevelInfo, msg) }
+func logError(ctx context.Context, msg string) { logWith(ctx, slog.LevelError, msg) }
 
-func logInfo(msg string)  { logLine("INFO", msg) }
-func logError(msg string) { logLine("ERROR", msg) }
+func logInfof(ctx context.Context, format string, args ...any) {
+	logWith(ctx, slog.LevelInfo, fmt.Sprintf(format, args...))
+}
 
-func logInfof(format string, args ...any) {
-	logLine("INFO", fmt.Sprintf(format, args...))
+func logErrorf(ctx context.Context, format string, args ...any) {
+	logWith(ctx, slog.LevelError, fmt.Sprintf(format, args...))
 }
 
-func logErrorf(format string, args ...any) {
-	logLine("ERROR", fmt.Sprintf(format, args...))
+// newRequestID 生成一个随机 UUIDv4，挂在每个请求的 X-Request-ID 头和日志里。
+func newRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return fmt.Sprintf("req-%d", time.Now().UnixNano())
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
 }
 
-func logSep() {
-	logInfo("===========================================================")
+func envIntDefault(key string, def int) int {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return def
+	}
+	return n
 }
 
+func envBoolDefault(key string, def bool) bool {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return def
+	}
+	return b
+}
 
 // API 访问日志（含来源 IP、状态码、耗时）
 func clientIP(r *http.Request) string {
@@ -156,10 +214,16 @@ func (sr *statusRecorder) WriteHeader(code int) {
 	sr.ResponseWriter.WriteHeader(code)
 }
 
-// 日志中间件
+// 日志中间件：生成 request id，挂到 context 和响应头上，供后续所有日志行关联。
 func loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
+
+		rid := newRequestID()
+		w.Header().Set("X-Request-ID", rid)
+		ctx := context.WithValue(r.Context(), ctxKeyRequestID{}, rid)
+		r = r.WithContext(ctx)
+
 		sr := &statusRecorder{ResponseWriter: w, status: 200}
 
 		ip := clientIP(r)
@@ -167,23 +231,100 @@ func loggingMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(sr, r)
 
 		cost := time.Since(start).Milliseconds()
-		logInfof("API ip=%s %s %s status=%d cost=%dms ua=%q",
+		logInfof(ctx, "API ip=%s %s %s status=%d cost=%dms ua=%q",
 			ip, r.Method, r.URL.Path, sr.status, cost, r.UserAgent())
 	})
 }
 
+type ctxKeySession struct{}
+
+func sessionFromContext(ctx context.Context) (auth.Session, bool) {
+	sess, ok := ctx.Value(ctxKeySession{}).(auth.Session)
+	return sess, ok
+}
+
+// authMiddleware 要求除 /api/login 外的所有 /api/* 与 /image 请求携带有效的会话 cookie，
+// 静态资源（前端页面本身）不受影响，由前端页面自己处理登录跳转。
+func authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		needsAuth := path == "/image" || (strings.HasPrefix(path, "/api/") && path != "/api/login")
+		if !needsAuth {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cookie, err := r.Cookie(auth.CookieName)
+		if err != nil {
+			http.Error(w, "未登录", http.StatusUnauthorized)
+			return
+		}
+
+		sess, ok := authManager.Authenticate(cookie.Value)
+		if !ok {
+			http.Error(w, "登录已过期", http.StatusUnauthorized)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ctxKeySession{}, sess)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// requireAdmin 包一层角色校验，供 DELETE /api/records/{id}、POST /api/labels 这类管理接口使用。
+func requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sess, ok := sessionFromContext(r.Context())
+		if !ok || sess.Role != "admin" {
+			http.Error(w, "仅管理员可操作", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// startStatsSnapshotLoop 每隔 N 分钟（STATS_SNAPSHOT_INTERVAL_MIN，默认 5）记录一次
+// total/valid/error 的 stats_snapshot 事件，方便日志聚合系统不必轮询 /api/stats
+// 也能画出签到健康度曲线。
+func startStatsSnapshotLoop(ctx context.Context, logCtx context.Context) {
+	interval := time.Duration(envIntDefault("STATS_SNAPSHOT_INTERVAL_MIN", 5)) * time.Minute
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s, err := db.Stats(store.StatsRange{})
+				if err != nil {
+					logErrorf(logCtx, "stats_snapshot 查询失败: %v", err)
+					continue
+				}
+				if logger != nil {
+					logger.Log(logCtx, slog.LevelInfo, "stats_snapshot",
+						slog.Int64("total", s.Total),
+						slog.Int64("valid", s.Valid),
+						slog.Int64("error", s.Error))
+				}
+			}
+		}
+	}()
+}
+
 func main() {
 	dataDir = os.Getenv("DATA_DIR")
 	if dataDir == "" {
 		dataDir = "../data"
 	}
 
+	appCtx := context.Background()
+
 	logPath, err := initLogger(dataDir)
 	if err != nil {
-		log.SetFlags(0)
-		log.Printf("logger init failed: %v", err)
+		fmt.Fprintf(os.Stderr, "logger init failed: %v\n", err)
 	} else {
-		logInfof("log file: %s", logPath)
+		logInfof(appCtx, "log file: %s", logPath)
 	}
 	defer closeLogger()
 
@@ -202,12 +343,81 @@ func main() {
 		staticDir = "./static"
 	}
 
-	logSep()
-	logInfof("使用 data 目录: %s", dataDir)
-	logInfof("使用 CSV 日志: %s", csvPath)
-	logInfof("使用 label_map: %s", labelMapPath)
-	logInfof("使用静态目录: %s", staticDir)
-	logSep()
+	logInfof(appCtx, "使用 data 目录: %s", dataDir)
+	logInfof(appCtx, "使用 CSV 日志: %s", csvPath)
+	logInfof(appCtx, "使用 label_map: %s", labelMapPath)
+	logInfof(appCtx, "使用静态目录: %s", staticDir)
+
+	// 存储层：默认用 dataDir 下的单文件 SQLite，设置 DB_DSN 则改用 MySQL。
+	dbDSN := os.Getenv("DB_DSN")
+	if dbDSN != "" {
+		db, err = store.NewMySQL(dbDSN)
+	} else {
+		db, err = store.NewSQLite(filepath.Join(dataDir, "piface.db"))
+	}
+	if err != nil {
+		logErrorf(appCtx, "初始化存储层失败: %v", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	// 登录与会话：users.json 缺失时视为没有任何用户，所有登录请求都会被拒绝，
+	// 但不影响服务启动（便于还没配置账号密码的部署先跑起来）。
+	usersPath := os.Getenv("USERS_FILE")
+	if usersPath == "" {
+		usersPath = filepath.Join(dataDir, "users.json")
+	}
+	users, err := auth.LoadUsers(usersPath)
+	if err != nil {
+		logErrorf(appCtx, "加载用户表失败（登录功能不可用）: %v", err)
+		users = map[string]auth.User{}
+	}
+
+	var sessionStore auth.Store
+	if sessionDBPath := os.Getenv("SESSION_DB_PATH"); sessionDBPath != "" {
+		sessionStore, err = auth.NewBoltStore(sessionDBPath)
+		if err != nil {
+			logErrorf(appCtx, "打开会话数据库失败，退回内存会话: %v", err)
+			sessionStore = auth.NewMemoryStore()
+		}
+	} else {
+		sessionStore = auth.NewMemoryStore()
+	}
+	authManager = auth.NewManager(users, sessionStore, auth.DefaultTTL)
+
+	// 启动时把已存在的 CSV 一次性导入数据库，之后由 ingester 增量追加新行。
+	ingester := store.NewIngester(csvPath, db)
+	ingester.OnError = func(err error) { logErrorf(appCtx, "CSV 增量导入失败: %v", err) }
+	if err := ingester.Backfill(); err != nil {
+		logErrorf(appCtx, "CSV 首次导入失败: %v", err)
+	}
+
+	ingestCtx, cancelIngest := context.WithCancel(appCtx)
+	defer cancelIngest()
+	go ingester.Run(ingestCtx, 5*time.Second)
+
+	startStatsSnapshotLoop(ingestCtx, appCtx)
+
+	// 告警监控：规则存于 dataDir/alerts.yaml，SIGHUP 热加载。
+	monitor := alert.NewMonitor(db, filepath.Join(dataDir, "alerts.yaml"), func(err error) {
+		logErrorf(appCtx, "告警监控出错: %v", err)
+	})
+	if err := monitor.Reload(); err != nil {
+		logErrorf(appCtx, "加载 alerts.yaml 失败: %v", err)
+	}
+	go monitor.Run(ingestCtx, time.Minute)
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			if err := monitor.Reload(); err != nil {
+				logErrorf(appCtx, "重新加载 alerts.yaml 失败: %v", err)
+			} else {
+				logInfo(appCtx, "已重新加载 alerts.yaml")
+			}
+		}
+	}()
 
 	mux := http.NewServeMux()
 
@@ -217,14 +427,23 @@ func main() {
 
 	// API
 	mux.HandleFunc("/api/records", handleRecords)
+	mux.HandleFunc("/api/records/", requireAdmin(handleRecordDelete))
 	mux.HandleFunc("/api/stats", handleStats)
+	mux.HandleFunc("/api/report.csv", handleReportCSV)
+
+	// 登录 / 登出
+	mux.HandleFunc("/api/login", handleLogin)
+	mux.HandleFunc("/api/logout", handleLogout)
+
+	// 标签重命名（管理员）
+	mux.HandleFunc("/api/labels", requireAdmin(handleLabels))
 
 	// 图片预览：/image?path=unknow/xxx.jpg （相对于 dataDir）
 	mux.HandleFunc("/image", handleImage)
 
 	srv := &http.Server{
 		Addr:    ":8080",
-		Handler: loggingMiddleware(mux),
+		Handler: loggingMiddleware(authMiddleware(mux)),
 	}
 
 	// 捕获 SIGINT/SIGTERM，记录关闭日志
@@ -232,72 +451,36 @@ func main() {
 	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
 
 	go func() {
-		logSep()
-		logInfo("服务启动成功：http://0.0.0.0:8080")
-		logSep()
+		logInfo(appCtx, "服务启动成功：http://0.0.0.0:8080")
 		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			logErrorf("server listen error: %v", err)
+			logErrorf(appCtx, "server listen error: %v", err)
 		}
 	}()
 
 	sig := <-stop
-	logSep()
-	logInfof("收到关闭信号: %s", sig.String())
-	logInfo("开始优雅关闭服务...")
+	logInfof(appCtx, "收到关闭信号: %s", sig.String())
+	logInfo(appCtx, "开始优雅关闭服务...")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(appCtx, 5*time.Second)
 	defer cancel()
 
-	if err := srv.Shutdown(ctx); err != nil {
-		logErrorf("优雅关闭失败: %v", err)
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logErrorf(appCtx, "优雅关闭失败: %v", err)
 	} else {
-		logInfo("服务已关闭")
+		logInfo(appCtx, "服务已关闭")
 	}
-	logSep()
 }
 
-// 每次请求重新读取 CSV，保证看到最新记录
-// 严格要求：无表头、且每行必须恰好 5 列（多/少都跳过）
-// [0]=timestamp, [1]=match_name, [2]=similarity, [3]=threshold, [4]=status
-func loadRecordsFromCSV(path string) ([]Record, error) {
-	f, err := os.Open(path)
-	if err != nil {
-		return nil, err
+// toAPIRecord 把存储层的 Record 转换成面向前端的 JSON 结构。
+func toAPIRecord(rec store.Record) Record {
+	return Record{
+		ID:         int(rec.ID),
+		Timestamp:  rec.Timestamp.Format("2006-01-02 15:04:05"),
+		MatchName:  rec.MatchName,
+		Similarity: rec.Similarity,
+		Threshold:  rec.Threshold,
+		Status:     rec.Status,
 	}
-	defer f.Close()
-
-	reader := csv.NewReader(f)
-	reader.FieldsPerRecord = -1
-
-	var result []Record
-	id := 1
-
-	for {
-		row, err := reader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return nil, err
-		}
-
-		// 严格：只接受 5 列
-		if len(row) != 5 {
-			continue
-		}
-
-		rec := Record{
-			ID:         id,
-			Timestamp:  strings.TrimSpace(row[0]),
-			MatchName:  strings.TrimSpace(row[1]),
-			Similarity: strings.TrimSpace(row[2]),
-			Threshold:  strings.TrimSpace(row[3]),
-			Status:     strings.TrimSpace(row[4]),
-		}
-		result = append(result, rec)
-		id++
-	}
-	return result, nil
 }
 
 // 读取 label_map.json，返回 ID -> 姓名 的映射
@@ -316,29 +499,10 @@ func loadLabelMap(path string) (map[string]string, error) {
 }
 
 // /api/records?status=MATCH|ERROR|NO_FACE&q=...&page=1&pageSize=20
-// 列表只是原始行，不做按天去重，方便排查
+// 列表只是原始行，不做按天去重，方便排查。查询走数据库索引，不再在 Go 里全量扫描。
 func handleRecords(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 
-	records, err := loadRecordsFromCSV(csvPath)
-	if err != nil {
-		logErrorf("读取 CSV 失败: %v", err)
-		resp := struct {
-			Data     []Record `json:"data"`
-			Total    int      `json:"total"`
-			Page     int      `json:"page"`
-			PageSize int      `json:"pageSize"`
-		}{
-			Data:     []Record{},
-			Total:    0,
-			Page:     1,
-			PageSize: 20,
-		}
-		_ = json.NewEncoder(w).Encode(resp)
-		return
-	}
-
-	// 解析分页参数
 	page := parseIntDefault(r.URL.Query().Get("page"), 1)
 	pageSize := parseIntDefault(r.URL.Query().Get("pageSize"), 20)
 	if page < 1 {
@@ -351,53 +515,26 @@ func handleRecords(w http.ResponseWriter, r *http.Request) {
 		pageSize = 500
 	}
 
-	// 过滤条件
-	statusFilter := strings.TrimSpace(r.URL.Query().Get("status"))
-	search := strings.TrimSpace(r.URL.Query().Get("q"))
-
-	var filtered []Record
-	for _, rec := range records {
-		s := strings.TrimSpace(rec.Status)
-
-		// 状态过滤
-		if statusFilter != "" && !strings.EqualFold(s, statusFilter) {
-			continue
-		}
-
-		// 模糊搜索（按姓名/状态）
-		if search != "" {
-			if !containsFold(rec.MatchName, search) && !containsFold(rec.Status, search) {
-				continue
-			}
-		}
+	from, to, _, _ := parseDateRange(r)
 
-		filtered = append(filtered, rec)
+	filter := store.Filter{
+		From:   from,
+		To:     to,
+		Status: strings.TrimSpace(r.URL.Query().Get("status")),
+		Person: strings.TrimSpace(r.URL.Query().Get("person")),
+		Search: strings.TrimSpace(r.URL.Query().Get("q")),
 	}
 
-	// 按时间倒序排序
-	sort.Slice(filtered, func(i, j int) bool {
-		ti, err1 := parseTimestamp(filtered[i].Timestamp)
-		tj, err2 := parseTimestamp(filtered[j].Timestamp)
-		if err1 != nil && err2 != nil {
-			return filtered[i].ID > filtered[j].ID
-		}
-		if err1 != nil {
-			return false
-		}
-		if err2 != nil {
-			return true
-		}
-		return ti.After(tj)
-	})
-
-	total := len(filtered)
-	start := (page - 1) * pageSize
-	if start > total {
-		start = total
+	result, err := db.ListRecords(filter, store.Page{Number: page, Size: pageSize})
+	if err != nil {
+		logErrorf(r.Context(), "查询记录失败: %v", err)
+		http.Error(w, "查询记录失败", http.StatusInternalServerError)
+		return
 	}
-	end := start + pageSize
-	if end > total {
-		end = total
+
+	data := make([]Record, 0, len(result.Records))
+	for _, rec := range result.Records {
+		data = append(data, toAPIRecord(rec))
 	}
 
 	resp := struct {
@@ -406,8 +543,8 @@ func handleRecords(w http.ResponseWriter, r *http.Request) {
 		Page     int      `json:"page"`
 		PageSize int      `json:"pageSize"`
 	}{
-		Data:     filtered[start:end],
-		Total:    total,
+		Data:     data,
+		Total:    int(result.Total),
 		Page:     page,
 		PageSize: pageSize,
 	}
@@ -417,108 +554,37 @@ func handleRecords(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// /api/stats 统计接口：按“同一人同一天只算一次”
+// /api/stats 统计接口：按“同一人同一天只算一次”，聚合全部交给数据库的 GROUP BY。
 func handleStats(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 
-	records, err := loadRecordsFromCSV(csvPath)
+	from, to, fromStr, toStr := parseDateRange(r)
+	person := strings.TrimSpace(r.URL.Query().Get("person"))
+
+	s, err := db.Stats(store.StatsRange{From: from, To: to, Person: person})
 	if err != nil {
-		logErrorf("读取 CSV 失败: %v", err)
+		logErrorf(r.Context(), "统计查询失败: %v", err)
 		_ = json.NewEncoder(w).Encode(StatsResponse{})
 		return
 	}
 
-	var stats StatsResponse
-	stats.Total = len(records)
-
-	// 统计原始状态数量 + 按“同一人同一天”去重的签到
-	personDaySet := make(map[string]map[string]struct{})                   // person -> set(date)
-	dayPeopleSet := make(map[string]map[string]struct{})                  // date -> set(person)
-	monthPersonDaysSet := make(map[string]map[string]map[string]struct{}) // month -> person -> set(date)
-
-	for _, rec := range records {
-		sTrim := strings.TrimSpace(rec.Status)
-		upperStatus := strings.ToUpper(sTrim)
-
-		switch upperStatus {
-		case "MATCH":
-			stats.MatchRaw++
-
-			t, err := parseTimestamp(rec.Timestamp)
-			if err != nil {
-				continue
-			}
-
-			// 过滤掉 UNKNOWN / NO_FACE / 空名字，只统计真实人员
-			rawName := strings.TrimSpace(rec.MatchName)
-			upperName := strings.ToUpper(rawName)
-			if rawName == "" || upperName == "UNKNOWN" || upperName == "NO_FACE" {
-				continue
-			}
-			person := rawName
-
-			date := t.Format("2006-01-02")
-			month := t.Format("2006-01")
-
-			if _, ok := personDaySet[person]; !ok {
-				personDaySet[person] = make(map[string]struct{})
-			}
-			if _, exists := personDaySet[person][date]; exists {
-				continue
-			}
-
-			personDaySet[person][date] = struct{}{}
-			stats.Valid++
-
-			if _, ok := dayPeopleSet[date]; !ok {
-				dayPeopleSet[date] = make(map[string]struct{})
-			}
-			dayPeopleSet[date][person] = struct{}{}
-
-			if _, ok := monthPersonDaysSet[month]; !ok {
-				monthPersonDaysSet[month] = make(map[string]map[string]struct{})
-			}
-			if _, ok := monthPersonDaysSet[month][person]; !ok {
-				monthPersonDaysSet[month][person] = make(map[string]struct{})
-			}
-			monthPersonDaysSet[month][person][date] = struct{}{}
-
-		case "ERROR":
-			stats.Error++
-		case "NO_FACE":
-			stats.NoFace++
-		default:
-			if upperStatus != "" {
-				stats.OtherInvalid++
-			}
-		}
+	stats := StatsResponse{
+		Range:        RangeEcho{From: fromStr, To: toStr},
+		Total:        int(s.Total),
+		MatchRaw:     int(s.MatchRaw),
+		Valid:        int(s.Valid),
+		Error:        int(s.Error),
+		NoFace:       int(s.NoFace),
+		OtherInvalid: int(s.OtherInvalid),
 	}
-
-	for person, daySet := range personDaySet {
-		for date := range daySet {
-			stats.PersonDay = append(stats.PersonDay, PersonDayCount{
-				Person: person,
-				Date:   date,
-				Count:  1,
-			})
-		}
+	for _, pd := range s.PersonDay {
+		stats.PersonDay = append(stats.PersonDay, PersonDayCount{Person: pd.Person, Date: pd.Date, Count: 1})
 	}
-
-	for date, set := range dayPeopleSet {
-		stats.DayPeople = append(stats.DayPeople, DayPeopleCount{
-			Date:   date,
-			People: len(set),
-		})
+	for _, dp := range s.DayPeople {
+		stats.DayPeople = append(stats.DayPeople, DayPeopleCount{Date: dp.Date, People: dp.People})
 	}
-
-	for month, personSet := range monthPersonDaysSet {
-		for person, daySet := range personSet {
-			stats.MonthPerson = append(stats.MonthPerson, MonthPersonDays{
-				Month:  month,
-				Person: person,
-				Days:   len(daySet),
-			})
-		}
+	for _, mp := range s.MonthPerson {
+		stats.MonthPerson = append(stats.MonthPerson, MonthPersonDays{Month: mp.Month, Person: mp.Person, Days: mp.Days})
 	}
 
 	sort.Slice(stats.PersonDay, func(i, j int) bool {
@@ -556,16 +622,185 @@ func handleStats(w http.ResponseWriter, r *http.Request) {
 		sort.Strings(stats.AllPersons)
 	} else {
 		// 不报错给前端，但记录到 2.txt
-		logErrorf("读取 label_map 失败: %v", err)
+		logErrorf(r.Context(), "读取 label_map 失败: %v", err)
 		stats.AllPersons = []string{}
 		stats.LabelMap = map[string]string{}
 	}
 
+	// AbsentPersons = AllPersons - 本次范围内出现过的人，方便前端直接渲染“今天谁没来”
+	seen := make(map[string]struct{}, len(stats.PersonDay))
+	for _, pd := range stats.PersonDay {
+		seen[pd.Person] = struct{}{}
+	}
+	for _, name := range stats.AllPersons {
+		if _, ok := seen[name]; !ok {
+			stats.AbsentPersons = append(stats.AbsentPersons, name)
+		}
+	}
+
 	if err := json.NewEncoder(w).Encode(stats); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
 
+// /api/report.csv?from=&to=&person= 把 handleStats 同样的 PersonDay/DayPeople
+// 两张表导出为可下载的 CSV，日期范围规则与 handleStats 一致。
+func handleReportCSV(w http.ResponseWriter, r *http.Request) {
+	from, to, _, _ := parseDateRange(r)
+	person := strings.TrimSpace(r.URL.Query().Get("person"))
+
+	s, err := db.Stats(store.StatsRange{From: from, To: to, Person: person})
+	if err != nil {
+		logErrorf(r.Context(), "导出报表失败: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="report.csv"`)
+
+	cw := csv.NewWriter(w)
+	defer cw.Flush()
+
+	_ = cw.Write([]string{"person", "date"})
+	for _, pd := range s.PersonDay {
+		_ = cw.Write([]string{pd.Person, pd.Date})
+	}
+
+	_ = cw.Write([]string{})
+	_ = cw.Write([]string{"date", "people"})
+	for _, dp := range s.DayPeople {
+		_ = cw.Write([]string{dp.Date, strconv.Itoa(dp.People)})
+	}
+}
+
+// POST /api/login {"username":"...","password":"..."}
+// 登录成功后签发 HttpOnly+SameSite=Lax 的会话 cookie，过期时间与会话一致。
+func handleLogin(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	sess, err := authManager.Login(req.Username, req.Password)
+	if err != nil {
+		logErrorf(r.Context(), "登录失败 username=%s: %v", req.Username, err)
+		http.Error(w, "用户名或密码错误", http.StatusUnauthorized)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     auth.CookieName,
+		Value:    sess.Token,
+		Path:     "/",
+		Expires:  sess.ExpiresAt,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	logInfof(r.Context(), "登录成功 username=%s role=%s", sess.Username, sess.Role)
+	_ = json.NewEncoder(w).Encode(struct {
+		Username string `json:"username"`
+		Role     string `json:"role"`
+	}{Username: sess.Username, Role: sess.Role})
+}
+
+// POST /api/logout
+func handleLogout(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie(auth.CookieName)
+	if err == nil {
+		_ = authManager.Logout(cookie.Value)
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     auth.CookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DELETE /api/records/{id}，仅管理员可用。
+func handleRecordDelete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	sess, _ := sessionFromContext(r.Context())
+
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/records/")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	if err := db.DeleteRecord(uint(id)); err != nil {
+		logErrorf(r.Context(), "删除记录失败 id=%d: %v", id, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	logInfof(r.Context(), "管理员 %s 删除了记录 id=%d", sess.Username, id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /api/labels {"id":"...","name":"..."}，重命名 label_map.json 中的一条映射，仅管理员可用。
+func handleLabels(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.ID == "" {
+		http.Error(w, "missing id", http.StatusBadRequest)
+		return
+	}
+
+	labelMap, err := loadLabelMap(labelMapPath)
+	if err != nil {
+		labelMap = map[string]string{}
+	}
+	labelMap[req.ID] = req.Name
+
+	data, err := json.MarshalIndent(labelMap, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := os.WriteFile(labelMapPath, data, 0o644); err != nil {
+		logErrorf(r.Context(), "写入 label_map 失败: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	sess, _ := sessionFromContext(r.Context())
+	logInfof(r.Context(), "管理员 %s 重命名标签 id=%s name=%s", sess.Username, req.ID, req.Name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // 图片预览：/image?path=unknow/xxx.jpg （相对于 dataDir）
 func handleImage(w http.ResponseWriter, r *http.Request) {
 	path := r.URL.Query().Get("path")
@@ -574,9 +809,16 @@ func handleImage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	fullPath := filepath.Join(dataDir, path)
+	fullPath, err := resolveDataPath(path)
+	if err != nil {
+		logErrorf(r.Context(), "图片路径越界: path=%s err=%v", path, err)
+		http.Error(w, "invalid path", http.StatusBadRequest)
+		return
+	}
+
 	f, err := os.Open(fullPath)
 	if err != nil {
+		logErrorf(r.Context(), "图片未找到: path=%s err=%v", path, err)
 		http.Error(w, "file not found", http.StatusNotFound)
 		return
 	}
@@ -595,6 +837,23 @@ func handleImage(w http.ResponseWriter, r *http.Request) {
 
 // 工具函数
 
+// resolveDataPath 把 /image?path= 传来的相对路径解析到 dataDir 下的绝对路径，
+// 并校验结果仍落在 dataDir 内，防止 "../../etc/passwd" 这类路径穿越读到任意文件。
+func resolveDataPath(relPath string) (string, error) {
+	absDataDir, err := filepath.Abs(dataDir)
+	if err != nil {
+		return "", err
+	}
+	absPath, err := filepath.Abs(filepath.Join(absDataDir, relPath))
+	if err != nil {
+		return "", err
+	}
+	if absPath != absDataDir && !strings.HasPrefix(absPath, absDataDir+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %q escapes data dir", relPath)
+	}
+	return absPath, nil
+}
+
 func parseIntDefault(s string, def int) int {
 	if s == "" {
 		return def
@@ -606,44 +865,42 @@ func parseIntDefault(s string, def int) int {
 	return n
 }
 
-func containsFold(s, substr string) bool {
-	if substr == "" {
-		return true
-	}
-	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
-}
-
-func parseTimestamp(ts string) (time.Time, error) {
-	ts = strings.TrimSpace(ts)
-	if ts == "" {
-		return time.Time{}, errors.New("empty timestamp")
-	}
-
-	layouts := []string{
-		time.RFC3339,
-		"2006-01-02 15:04:05",
-		"2006/01/02 15:04:05",
-		"2006-01-02 15:04",
-		"2006/01/02 15:04",
-		"2006-01-02",
-		"2006/01/02",
-	}
-
-	for _, layout := range layouts {
-		if t, err := time.Parse(layout, ts); err == nil {
-			return t, nil
+// parseDateRange 解析 from/to（YYYY-MM-DD，两端都是闭区间的日期），返回左闭右开的
+// 时间范围 [from, to)，to 为最后一天的次日 0 点。两者都缺省时默认查昨天一天，
+// 贴合报表类页面打开即看「昨天」的习惯用法。
+//
+// store.parseTimestamp 对不带时区的 records.csv 时间戳一律按 UTC 解析，所以这里的默认
+// “昨天”边界也必须按 UTC 计算，否则本地时区的午夜和入库记录的 UTC 午夜对不上，会在
+// 时区偏移的宽度内把应该算“昨天”的记录漏掉或多算。返回值里的 fromStr/toStr 供回显和
+// 文件名使用。
+func parseDateRange(r *http.Request) (from, to time.Time, fromStr, toStr string) {
+	fromStr = strings.TrimSpace(r.URL.Query().Get("from"))
+	toStr = strings.TrimSpace(r.URL.Query().Get("to"))
+
+	if fromStr == "" && toStr == "" {
+		yesterday := truncateToDay(time.Now().UTC().AddDate(0, 0, -1))
+		from = yesterday
+		to = yesterday.AddDate(0, 0, 1)
+		fromStr = yesterday.Format("2006-01-02")
+		toStr = fromStr
+		return from, to, fromStr, toStr
+	}
+
+	if fromStr != "" {
+		if t, err := time.Parse("2006-01-02", fromStr); err == nil {
+			from = t
 		}
 	}
-
-	// 如果有小数秒，截掉小数部分再试
-	if i := strings.Index(ts, "."); i != -1 {
-		ts2 := ts[:i]
-		for _, layout := range layouts {
-			if t, err := time.Parse(layout, ts2); err == nil {
-				return t, nil
-			}
+	if toStr != "" {
+		if t, err := time.Parse("2006-01-02", toStr); err == nil {
+			to = t.AddDate(0, 0, 1) // to 是闭区间的最后一天，查询时用次日 0 点当上界
 		}
 	}
+	return from, to, fromStr, toStr
+}
 
-	return time.Time{}, errors.New("cannot parse timestamp")
+func truncateToDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
 }
+
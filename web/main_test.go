@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pi-face/auth"
+)
+
+func TestRequireAdmin_RejectsNonAdmin(t *testing.T) {
+	called := false
+	h := requireAdmin(func(w http.ResponseWriter, r *http.Request) { called = true })
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/records/1", nil)
+	ctx := context.WithValue(req.Context(), ctxKeySession{}, auth.Session{Username: "viewer", Role: "viewer"})
+	rec := httptest.NewRecorder()
+
+	h(rec, req.WithContext(ctx))
+
+	if called {
+		t.Errorf("非 admin 角色不应放行到下游 handler")
+	}
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("状态码 = %d, 期望 %d", rec.Code, http.StatusForbidden)
+	}
+}
+
+func TestRequireAdmin_RejectsMissingSession(t *testing.T) {
+	called := false
+	h := requireAdmin(func(w http.ResponseWriter, r *http.Request) { called = true })
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/records/1", nil)
+	rec := httptest.NewRecorder()
+
+	h(rec, req)
+
+	if called {
+		t.Errorf("没有会话时不应放行到下游 handler")
+	}
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("状态码 = %d, 期望 %d", rec.Code, http.StatusForbidden)
+	}
+}
+
+func TestRequireAdmin_AllowsAdmin(t *testing.T) {
+	called := false
+	h := requireAdmin(func(w http.ResponseWriter, r *http.Request) { called = true })
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/records/1", nil)
+	ctx := context.WithValue(req.Context(), ctxKeySession{}, auth.Session{Username: "admin", Role: "admin"})
+	rec := httptest.NewRecorder()
+
+	h(rec, req.WithContext(ctx))
+
+	if !called {
+		t.Errorf("admin 角色应放行到下游 handler")
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("状态码 = %d, 期望 %d", rec.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,103 @@
+package store
+
+import (
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// sniffSize 是编码嗅探时读取的样本大小。
+const sniffSize = 4096
+
+// resolveEncodingEnv 解析 RECORDS_CSV_ENCODING（auto|utf-8|gbk|gb18030），
+// 未设置时按 auto 处理。nil 表示按 UTF-8 原样读取，不做转换。
+func resolveEncodingEnv(path string) encoding.Encoding {
+	mode := strings.ToLower(strings.TrimSpace(os.Getenv("RECORDS_CSV_ENCODING")))
+	switch mode {
+	case "utf-8", "utf8":
+		return nil
+	case "gbk":
+		return simplifiedchinese.GBK
+	case "gb18030":
+		return simplifiedchinese.GB18030
+	default: // "" 或 "auto"
+		return detectEncoding(sniff(path))
+	}
+}
+
+func sniff(path string) []byte {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	buf := make([]byte, sniffSize)
+	n, _ := f.Read(buf)
+	return buf[:n]
+}
+
+// detectEncoding 对 records.csv 的开头做一次粗略的 UTF-8 / GBK(GB18030) 判断：
+// 合法 UTF-8（含 BOM）直接当 UTF-8 处理；否则尝试整段按 GB18030 解码，
+// 能无错还原就认为是 GBK/GB18030（GBK 是 GB18030 的子集，用后者的解码器即可兼容两者）。
+func detectEncoding(sample []byte) encoding.Encoding {
+	if len(sample) == 0 {
+		return nil
+	}
+	if len(sample) >= 3 && sample[0] == 0xEF && sample[1] == 0xBB && sample[2] == 0xBF {
+		return nil
+	}
+	// sample 是 sniffSize 字节处的硬截断，可能恰好卡在一个多字节 UTF-8 字符中间；
+	// 那样的话 utf8.Valid 会误判为「非法」，而 GB18030 又几乎不会对任意字节报错，
+	// 于是把真正的 UTF-8 文件错判成 GBK。判断前去掉结尾不完整的那个字符。
+	if trimmed := trimIncompleteRuneTail(sample); utf8.Valid(trimmed) {
+		return nil
+	}
+	if _, err := simplifiedchinese.GB18030.NewDecoder().Bytes(sample); err == nil {
+		return simplifiedchinese.GB18030
+	}
+	return nil
+}
+
+// trimIncompleteRuneTail 去掉 b 末尾因采样截断而不完整的 UTF-8 字符（最多 3 字节），
+// 真正非法的字节序列保持原样，好让 utf8.Valid 仍能把它们判为非法。
+func trimIncompleteRuneTail(b []byte) []byte {
+	n := len(b)
+	limit := n - utf8.UTFMax
+	if limit < 0 {
+		limit = 0
+	}
+	for i := n - 1; i >= limit; i-- {
+		c := b[i]
+		if c < 0x80 {
+			// ASCII，rune 在这里结束，结尾不存在被截断的多字节字符。
+			return b
+		}
+		if c >= 0xC0 {
+			// 多字节字符的首字节：根据首字节本身判断该字符应占的字节数
+			// （不能用 utf8.DecodeRune，它对不完整序列和真正非法序列返回的 size 无法区分）。
+			// 如果样本剩余字节不够，说明是被采样截断的，去掉它。
+			if i+runeLenFromLeadByte(c) > n {
+				return b[:i]
+			}
+			return b
+		}
+		// 0x80-0xBF 是延续字节，继续向前找首字节。
+	}
+	return b
+}
+
+// runeLenFromLeadByte 按 UTF-8 首字节的高位模式返回该字符应有的总字节数。
+func runeLenFromLeadByte(c byte) int {
+	switch {
+	case c >= 0xF0:
+		return 4
+	case c >= 0xE0:
+		return 3
+	default: // 0xC0-0xDF
+		return 2
+	}
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// testdata/records_gbk.csv 是用 GBK 编码写的两行 records.csv，match_name 分别是
+// “张三”“李四”，模拟中文 Raspberry Pi 部署上最常见的编码场景。
+
+func TestDetectEncoding_GBK(t *testing.T) {
+	sample := sniff("testdata/records_gbk.csv")
+	if len(sample) == 0 {
+		t.Fatalf("未能读取 testdata/records_gbk.csv")
+	}
+
+	enc := detectEncoding(sample)
+	if enc != simplifiedchinese.GB18030 {
+		t.Fatalf("detectEncoding(GBK 样本) = %v, 期望 GB18030（GBK 的超集解码器）", enc)
+	}
+}
+
+func TestDetectEncoding_UTF8(t *testing.T) {
+	enc := detectEncoding([]byte("2024-07-27 09:00:00,张三,0.82,0.60,MATCH\n"))
+	if enc != nil {
+		t.Fatalf("detectEncoding(UTF-8 样本) = %v, 期望 nil（原样读取）", enc)
+	}
+}
+
+// TestDetectEncoding_UTF8_TruncatedMidRune 模拟 sniff() 在多字节 UTF-8 字符中间
+// 截断样本（如 4096 字节边界恰好切在“中”的 3 字节序列中间）：
+// 截断后的样本本身不再是合法 UTF-8，但不能因此被误判为 GB18030。
+func TestDetectEncoding_UTF8_TruncatedMidRune(t *testing.T) {
+	full := []byte("2024-07-27 09:00:00,张三,0.82,0.60,MATCH\n")
+	// “三” 的 UTF-8 编码是 3 字节，只截取前 2 字节，模拟采样边界切断。
+	cut := strings.Index(string(full), "三") + 2
+	enc := detectEncoding(full[:cut])
+	if enc != nil {
+		t.Fatalf("detectEncoding(截断到字符中间的 UTF-8 样本) = %v, 期望 nil（不应误判为 GB18030）", enc)
+	}
+}
+
+func TestResolveEncodingEnv_Auto(t *testing.T) {
+	t.Setenv("RECORDS_CSV_ENCODING", "")
+
+	enc := resolveEncodingEnv("testdata/records_gbk.csv")
+	if enc != simplifiedchinese.GB18030 {
+		t.Fatalf("resolveEncodingEnv(auto) = %v, 期望 GB18030", enc)
+	}
+
+	decoded, err := enc.NewDecoder().Bytes(mustRead(t, "testdata/records_gbk.csv"))
+	if err != nil {
+		t.Fatalf("解码 GBK 样本失败: %v", err)
+	}
+	if got := string(decoded); !strings.Contains(got, "张三") {
+		t.Fatalf("解码结果未包含预期的中文姓名: %q", got)
+	}
+}
+
+func TestResolveEncodingEnv_ForcedUTF8(t *testing.T) {
+	t.Setenv("RECORDS_CSV_ENCODING", "utf-8")
+
+	if enc := resolveEncodingEnv("testdata/records_gbk.csv"); enc != nil {
+		t.Fatalf("resolveEncodingEnv(强制 utf-8) = %v, 期望 nil", enc)
+	}
+}
+
+func mustRead(t *testing.T, path string) []byte {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("读取 %s 失败: %v", path, err)
+	}
+	return b
+}
@@ -0,0 +1,152 @@
+package store
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Ingester 持续跟踪 records.csv 的新增内容并写入 Store，
+// 通过记录文件 inode + 读取偏移量识别日志轮转（文件被截断或替换后重新从头读取）。
+type Ingester struct {
+	path  string
+	store Store
+
+	mu     sync.Mutex
+	offset int64
+	inode  uint64
+
+	// OnError 在单次轮询失败时被调用（可为空），用于让调用方接入自己的日志体系。
+	OnError func(error)
+}
+
+// NewIngester 创建一个指向 path 的增量导入器，写入 st。
+func NewIngester(path string, st Store) *Ingester {
+	return &Ingester{path: path, store: st}
+}
+
+// Backfill 在启动时对已存在的 CSV 做一次全量导入；重复调用是安全的，
+// 重复行会被 Store 按 (timestamp, match_name, source_offset) 去重忽略。
+func (g *Ingester) Backfill() error {
+	return g.poll()
+}
+
+// Run 周期性地检查文件是否有新内容，直到 ctx 被取消为止。
+func (g *Ingester) Run(ctx ctxLike, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := g.poll(); err != nil && g.OnError != nil {
+				g.OnError(err)
+			}
+		}
+	}
+}
+
+// ctxLike 避免直接依赖 context 包的具体类型，只要求一个 Done() 通道，
+// 方便调用方传入 context.Context。
+type ctxLike interface {
+	Done() <-chan struct{}
+}
+
+func (g *Ingester) poll() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	f, err := os.Open(g.path)
+	if err != nil {
+		return fmt.Errorf("打开 %s 失败: %w", g.path, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("stat %s 失败: %w", g.path, err)
+	}
+
+	inode := inodeOf(info)
+	if inode != g.inode || info.Size() < g.offset {
+		// 文件被轮转（inode 变化）或被截断，从头重新读取。
+		g.offset = 0
+		g.inode = inode
+	}
+
+	if _, err := f.Seek(g.offset, 0); err != nil {
+		return fmt.Errorf("seek %s 失败: %w", g.path, err)
+	}
+
+	// records.csv 在中文 Raspberry Pi 部署上常是 GBK/GB18030 编码；offset 必须按原始字节
+	// 计数，所以这里逐行解码成 UTF-8 再喂给 parseCSVLine，而不是用 transform.Reader 包住
+	// 整个文件流（那样字节数和解码后的行内容会对不上，offset 会跟着错位）。
+	enc := resolveEncodingEnv(g.path)
+
+	reader := bufio.NewReader(f)
+	for {
+		lineOffset := g.offset
+		raw, err := reader.ReadBytes('\n')
+		if err != nil {
+			// 写入方可能只刷新了半行（没有结尾的 '\n'），这部分内容既不能
+			// 解析也不能计入 offset，否则下次轮询会把剩余字节当成新行读取，
+			// 导致整行数据被错误拆分甚至丢弃。留到下次轮询再重新读取。
+			break
+		}
+		line := strings.TrimRight(string(raw), "\r\n")
+		if enc != nil {
+			if decoded, decErr := enc.NewDecoder().String(line); decErr == nil {
+				line = decoded
+			}
+		}
+		if rec, ok := parseCSVLine(line); ok {
+			rec.SourceOffset = lineOffset
+			if insertErr := g.store.InsertRecord(rec); insertErr != nil {
+				return insertErr
+			}
+		}
+		g.offset += int64(len(raw))
+	}
+
+	return nil
+}
+
+// parseCSVLine 解析单行 records.csv：无表头、严格 5 列，
+// [0]=timestamp, [1]=match_name, [2]=similarity, [3]=threshold, [4]=status。
+func parseCSVLine(line string) (Record, bool) {
+	if strings.TrimSpace(line) == "" {
+		return Record{}, false
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(line)).ReadAll()
+	if err != nil || len(rows) != 1 || len(rows[0]) != 5 {
+		return Record{}, false
+	}
+	row := rows[0]
+
+	ts, err := parseTimestamp(strings.TrimSpace(row[0]))
+	if err != nil {
+		return Record{}, false
+	}
+
+	return Record{
+		Timestamp:  ts,
+		MatchName:  strings.TrimSpace(row[1]),
+		Similarity: strings.TrimSpace(row[2]),
+		Threshold:  strings.TrimSpace(row[3]),
+		Status:     strings.TrimSpace(row[4]),
+	}, true
+}
+
+func inodeOf(info os.FileInfo) uint64 {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return st.Ino
+	}
+	return 0
+}
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeStore 是只收集 InsertRecord 调用的最小 Store 实现，供 Ingester 测试使用。
+type fakeStore struct {
+	records []Record
+}
+
+func (f *fakeStore) InsertRecord(rec Record) error {
+	f.records = append(f.records, rec)
+	return nil
+}
+
+func (f *fakeStore) ListRecords(Filter, Page) (ListResult, error) { return ListResult{}, nil }
+func (f *fakeStore) Stats(StatsRange) (Stats, error)              { return Stats{}, nil }
+func (f *fakeStore) DeleteRecord(uint) error                      { return nil }
+func (f *fakeStore) CountSince(string, time.Time) (int64, error)  { return 0, nil }
+func (f *fakeStore) CountUnknownMatches(time.Time) (int64, error) { return 0, nil }
+func (f *fakeStore) LastMatchAt() (time.Time, bool, error)        { return time.Time{}, false, nil }
+func (f *fakeStore) Close() error                                 { return nil }
+
+// TestIngesterBackfill_GBK 验证 Backfill 能识别 GBK 编码的 records.csv，
+// 把 match_name 正确解码成 UTF-8，且每行都带上各自的起始字节偏移。
+func TestIngesterBackfill_GBK(t *testing.T) {
+	st := &fakeStore{}
+	ing := NewIngester("testdata/records_gbk.csv", st)
+
+	if err := ing.Backfill(); err != nil {
+		t.Fatalf("Backfill 失败: %v", err)
+	}
+
+	if len(st.records) != 2 {
+		t.Fatalf("导入记录数 = %d, 期望 2", len(st.records))
+	}
+
+	if st.records[0].MatchName != "张三" {
+		t.Errorf("第一行 match_name = %q, 期望 张三", st.records[0].MatchName)
+	}
+	if st.records[1].MatchName != "李四" {
+		t.Errorf("第二行 match_name = %q, 期望 李四", st.records[1].MatchName)
+	}
+	if st.records[0].SourceOffset != 0 {
+		t.Errorf("第一行 SourceOffset = %d, 期望 0", st.records[0].SourceOffset)
+	}
+	if st.records[1].SourceOffset <= st.records[0].SourceOffset {
+		t.Errorf("第二行 SourceOffset (%d) 应大于第一行 (%d)", st.records[1].SourceOffset, st.records[0].SourceOffset)
+	}
+}
@@ -0,0 +1,42 @@
+package store
+
+import "time"
+
+// recordRow 是 records 表在数据库中的映射，字段与 records.csv 的 5 列一一对应。
+// (timestamp, match_name) 常被 ERROR/NO_FACE 行重复撞上（同一秒内出现多条、match_name
+// 为空），所以唯一索引里再加一列 SourceOffset（该行在 records.csv 中的起始字节偏移）
+// 作为区分度，既能去重“同一行被重复导入”，又不会把确实不同的两条记录误判成一条。
+type recordRow struct {
+	ID           uint      `gorm:"primarykey"`
+	Timestamp    time.Time `gorm:"not null;index;uniqueIndex:idx_ts_name_offset"`
+	MatchName    string    `gorm:"size:255;not null;uniqueIndex:idx_ts_name_offset"`
+	SourceOffset int64     `gorm:"not null;uniqueIndex:idx_ts_name_offset"`
+	Similarity   string    `gorm:"size:32"`
+	Threshold    string    `gorm:"size:32"`
+	Status       string    `gorm:"size:32;index"`
+}
+
+func (recordRow) TableName() string { return "records" }
+
+// Record 是对外暴露的记录结构，脱离 GORM 的内部字段。
+type Record struct {
+	ID           uint
+	Timestamp    time.Time
+	MatchName    string
+	SourceOffset int64
+	Similarity   string
+	Threshold    string
+	Status       string
+}
+
+func rowToRecord(row recordRow) Record {
+	return Record{
+		ID:           row.ID,
+		Timestamp:    row.Timestamp,
+		MatchName:    row.MatchName,
+		SourceOffset: row.SourceOffset,
+		Similarity:   row.Similarity,
+		Threshold:    row.Threshold,
+		Status:       row.Status,
+	}
+}
@@ -0,0 +1,367 @@
+// Package store 提供人脸识别记录的持久化与查询接口，
+// 底层由 GORM 驱动 SQLite（默认，单文件存放于 dataDir）或 MySQL（DB_DSN 指定）。
+//
+// 引入这一层是为了把 /api/records 与 /api/stats 的查询从“每次请求全量扫描 CSV”
+// 换成有索引的 SQL 查询，避免随 records.csv 增长而变慢。
+package store
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+)
+
+// Filter 描述 ListRecords 的查询条件，零值字段表示不过滤。
+type Filter struct {
+	From   time.Time
+	To     time.Time
+	Status string
+	Person string
+	// Search 对应旧版 /api/records?q= 的模糊搜索：同时匹配 match_name 和 status，
+	// 与 Person（按人名模糊筛选，同样是 LIKE %Person%）是两个独立的查询参数，不互相覆盖。
+	Search string
+}
+
+// Page 描述分页参数，Number 从 1 开始。
+type Page struct {
+	Number int
+	Size   int
+}
+
+// ListResult 是分页查询的结果。
+type ListResult struct {
+	Records []Record
+	Total   int64
+}
+
+// StatsRange 限定聚合统计的时间范围与人员，零值表示不限制。
+type StatsRange struct {
+	From   time.Time
+	To     time.Time
+	Person string
+}
+
+// PersonDay 对应“某人某日是否签到”（一天只算一次）。
+type PersonDay struct {
+	Person string
+	Date   string
+}
+
+// DayPeople 对应“某日有几个人来”（人数去重）。
+type DayPeople struct {
+	Date   string
+	People int
+}
+
+// MonthPersonDays 对应“某月某人来的天数”（天数去重）。
+type MonthPersonDays struct {
+	Month  string
+	Person string
+	Days   int
+}
+
+// Stats 聚合了 Stats(range) 返回的全部统计结果。
+type Stats struct {
+	Total        int64
+	MatchRaw     int64
+	Valid        int64
+	Error        int64
+	NoFace       int64
+	OtherInvalid int64
+	PersonDay    []PersonDay
+	DayPeople    []DayPeople
+	MonthPerson  []MonthPersonDays
+}
+
+// Store 是记录持久化与查询的统一接口。
+type Store interface {
+	InsertRecord(rec Record) error
+	ListRecords(f Filter, p Page) (ListResult, error)
+	Stats(r StatsRange) (Stats, error)
+	DeleteRecord(id uint) error
+
+	// CountSince、CountUnknownMatches、LastMatchAt 供告警规则（见 alert 包）使用，
+	// 不走 Stats 的全量聚合，直接按需查询更轻量。
+	CountSince(status string, since time.Time) (int64, error)
+	CountUnknownMatches(since time.Time) (int64, error)
+	LastMatchAt() (t time.Time, ok bool, err error)
+
+	Close() error
+}
+
+type gormStore struct {
+	db      *gorm.DB
+	dialect string // "sqlite" 或 "mysql"，用于拼接方言相关的日期函数
+}
+
+// NewSQLite 打开（或创建）dataDir 下的单文件 SQLite 数据库。
+func NewSQLite(path string) (Store, error) {
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		return nil, fmt.Errorf("打开 sqlite 失败: %w", err)
+	}
+	return newGormStore(db, "sqlite")
+}
+
+// NewMySQL 通过标准 DSN（如 user:pass@tcp(host:3306)/dbname?parseTime=true）连接 MySQL。
+func NewMySQL(dsn string) (Store, error) {
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		return nil, fmt.Errorf("打开 mysql 失败: %w", err)
+	}
+	return newGormStore(db, "mysql")
+}
+
+func newGormStore(db *gorm.DB, dialect string) (Store, error) {
+	if err := db.AutoMigrate(&recordRow{}); err != nil {
+		return nil, fmt.Errorf("自动迁移失败: %w", err)
+	}
+	return &gormStore{db: db, dialect: dialect}, nil
+}
+
+func (s *gormStore) Close() error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// InsertRecord 按 (timestamp, match_name, source_offset) 去重插入；已存在时静默忽略，
+// 供 CSV 增量导入（ingester）和启动时的一次性 backfill 使用。
+func (s *gormStore) InsertRecord(rec Record) error {
+	row := recordRow{
+		Timestamp:    rec.Timestamp,
+		MatchName:    rec.MatchName,
+		SourceOffset: rec.SourceOffset,
+		Similarity:   rec.Similarity,
+		Threshold:    rec.Threshold,
+		Status:       rec.Status,
+	}
+	if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&row).Error; err != nil {
+		return fmt.Errorf("插入记录失败: %w", err)
+	}
+	return nil
+}
+
+func (s *gormStore) ListRecords(f Filter, p Page) (ListResult, error) {
+	q := applyFilter(s.db.Model(&recordRow{}), f)
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return ListResult{}, fmt.Errorf("统计记录数失败: %w", err)
+	}
+
+	if p.Number < 1 {
+		p.Number = 1
+	}
+	if p.Size <= 0 {
+		p.Size = 20
+	}
+
+	var rows []recordRow
+	err := q.Order("timestamp DESC").
+		Limit(p.Size).
+		Offset((p.Number - 1) * p.Size).
+		Find(&rows).Error
+	if err != nil {
+		return ListResult{}, fmt.Errorf("查询记录失败: %w", err)
+	}
+
+	records := make([]Record, 0, len(rows))
+	for _, row := range rows {
+		records = append(records, rowToRecord(row))
+	}
+	return ListResult{Records: records, Total: total}, nil
+}
+
+// DeleteRecord 删除一条记录，仅供管理员角色使用（见 main.go 的 handleRecordDelete）。
+func (s *gormStore) DeleteRecord(id uint) error {
+	if err := s.db.Delete(&recordRow{}, id).Error; err != nil {
+		return fmt.Errorf("删除记录失败: %w", err)
+	}
+	return nil
+}
+
+func applyFilter(q *gorm.DB, f Filter) *gorm.DB {
+	if !f.From.IsZero() {
+		q = q.Where("timestamp >= ?", f.From)
+	}
+	if !f.To.IsZero() {
+		q = q.Where("timestamp < ?", f.To)
+	}
+	if f.Status != "" {
+		q = q.Where("status = ?", f.Status)
+	}
+	if f.Person != "" {
+		q = q.Where("match_name LIKE ?", "%"+f.Person+"%")
+	}
+	if f.Search != "" {
+		like := "%" + f.Search + "%"
+		q = q.Where("match_name LIKE ? OR status LIKE ?", like, like)
+	}
+	return q
+}
+
+// validPersonClause 过滤掉空姓名、UNKNOWN、NO_FACE，只保留真实人员的 MATCH 记录，
+// 与旧版 handleStats 中的判断保持一致。
+const validPersonClause = "status = ? AND match_name <> '' AND upper(match_name) NOT IN ('UNKNOWN', 'NO_FACE')"
+
+func (s *gormStore) Stats(r StatsRange) (Stats, error) {
+	var stats Stats
+
+	scoped := func() *gorm.DB {
+		q := s.db.Model(&recordRow{})
+		if !r.From.IsZero() {
+			q = q.Where("timestamp >= ?", r.From)
+		}
+		if !r.To.IsZero() {
+			q = q.Where("timestamp < ?", r.To)
+		}
+		if r.Person != "" {
+			q = q.Where("match_name LIKE ?", "%"+r.Person+"%")
+		}
+		return q
+	}
+
+	if err := scoped().Count(&stats.Total).Error; err != nil {
+		return Stats{}, fmt.Errorf("统计总数失败: %w", err)
+	}
+
+	var statusCounts []struct {
+		Status string
+		N      int64
+	}
+	err := scoped().Select("status, COUNT(*) as n").Group("status").Find(&statusCounts).Error
+	if err != nil {
+		return Stats{}, fmt.Errorf("按状态统计失败: %w", err)
+	}
+	for _, sc := range statusCounts {
+		switch strings.ToUpper(strings.TrimSpace(sc.Status)) {
+		case "MATCH":
+			stats.MatchRaw = sc.N
+		case "ERROR":
+			stats.Error = sc.N
+		case "NO_FACE":
+			stats.NoFace = sc.N
+		default:
+			if strings.TrimSpace(sc.Status) != "" {
+				stats.OtherInvalid += sc.N
+			}
+		}
+	}
+
+	var pdRows []struct {
+		Person string
+		Date   string
+	}
+	err = scoped().Where(validPersonClause, "MATCH").
+		Select(fmt.Sprintf("match_name as person, %s as date", s.dateExpr())).
+		Group("match_name, date").
+		Find(&pdRows).Error
+	if err != nil {
+		return Stats{}, fmt.Errorf("按人/日统计失败: %w", err)
+	}
+	for _, row := range pdRows {
+		stats.PersonDay = append(stats.PersonDay, PersonDay{Person: row.Person, Date: row.Date})
+	}
+	stats.Valid = int64(len(stats.PersonDay))
+
+	var dpRows []struct {
+		Date   string
+		People int
+	}
+	err = scoped().Where(validPersonClause, "MATCH").
+		Select(fmt.Sprintf("%s as date, COUNT(DISTINCT match_name) as people", s.dateExpr())).
+		Group("date").
+		Find(&dpRows).Error
+	if err != nil {
+		return Stats{}, fmt.Errorf("按日统计人数失败: %w", err)
+	}
+	for _, row := range dpRows {
+		stats.DayPeople = append(stats.DayPeople, DayPeople{Date: row.Date, People: row.People})
+	}
+
+	var mpRows []struct {
+		Month  string
+		Person string
+		Days   int
+	}
+	err = scoped().Where(validPersonClause, "MATCH").
+		Select(fmt.Sprintf("%s as month, match_name as person, COUNT(DISTINCT %s) as days", s.monthExpr(), s.dateExpr())).
+		Group("month, match_name").
+		Find(&mpRows).Error
+	if err != nil {
+		return Stats{}, fmt.Errorf("按月统计天数失败: %w", err)
+	}
+	for _, row := range mpRows {
+		stats.MonthPerson = append(stats.MonthPerson, MonthPersonDays{Month: row.Month, Person: row.Person, Days: row.Days})
+	}
+
+	return stats, nil
+}
+
+// CountSince 统计某个时间点之后某状态的记录数，供告警规则（如 ERROR 突增）使用。
+func (s *gormStore) CountSince(status string, since time.Time) (int64, error) {
+	var n int64
+	err := s.db.Model(&recordRow{}).
+		Where("status = ? AND timestamp >= ?", status, since).
+		Count(&n).Error
+	if err != nil {
+		return 0, fmt.Errorf("统计 %s 数量失败: %w", status, err)
+	}
+	return n, nil
+}
+
+// CountUnknownMatches 统计某个时间点之后 match_name 为 UNKNOWN 的 MATCH 记录数。
+func (s *gormStore) CountUnknownMatches(since time.Time) (int64, error) {
+	var n int64
+	err := s.db.Model(&recordRow{}).
+		Where("status = ? AND upper(match_name) = ? AND timestamp >= ?", "MATCH", "UNKNOWN", since).
+		Count(&n).Error
+	if err != nil {
+		return 0, fmt.Errorf("统计 UNKNOWN 匹配数量失败: %w", err)
+	}
+	return n, nil
+}
+
+// LastMatchAt 返回最近一次 status=MATCH 记录的时间（含 match_name 为 UNKNOWN 的匹配）；
+// ok=false 表示还没有任何 MATCH 记录。
+func (s *gormStore) LastMatchAt() (time.Time, bool, error) {
+	var row recordRow
+	err := s.db.Model(&recordRow{}).
+		Where("status = ?", "MATCH").
+		Order("timestamp DESC").
+		Limit(1).
+		Take(&row).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return time.Time{}, false, nil
+		}
+		return time.Time{}, false, fmt.Errorf("查询最近一次 MATCH 失败: %w", err)
+	}
+	return row.Timestamp, true, nil
+}
+
+// dateExpr/monthExpr 返回按方言拼接的日期截断表达式：按日两边都用 date()/DATE()，
+// 按月 SQLite 用 strftime，MySQL 用 DATE_FORMAT。
+func (s *gormStore) dateExpr() string {
+	if s.dialect == "mysql" {
+		return "DATE(timestamp)"
+	}
+	return "date(timestamp)"
+}
+
+func (s *gormStore) monthExpr() string {
+	if s.dialect == "mysql" {
+		return "DATE_FORMAT(timestamp, '%Y-%m')"
+	}
+	return "strftime('%Y-%m', timestamp)"
+}
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+// newTestStore 打开一个内存 SQLite 库，供聚合统计测试使用。
+func newTestStore(t *testing.T) Store {
+	t.Helper()
+	st, err := NewSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("打开内存 sqlite 失败: %v", err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+	return st
+}
+
+func mustInsert(t *testing.T, st Store, ts string, name, status string, offset int64) {
+	t.Helper()
+	tm, err := time.Parse("2006-01-02 15:04:05", ts)
+	if err != nil {
+		t.Fatalf("解析时间 %q 失败: %v", ts, err)
+	}
+	rec := Record{Timestamp: tm, MatchName: name, Status: status, SourceOffset: offset, Similarity: "0.8", Threshold: "0.6"}
+	if err := st.InsertRecord(rec); err != nil {
+		t.Fatalf("插入记录失败: %v", err)
+	}
+}
+
+// TestStats_PersonDayDedup 验证同一人同一天多次 MATCH 只计一次（PersonDay/DayPeople 去重），
+// 且 ERROR/NO_FACE/UNKNOWN 不计入有效签到。
+func TestStats_PersonDayDedup(t *testing.T) {
+	st := newTestStore(t)
+
+	mustInsert(t, st, "2024-07-27 08:00:00", "张三", "MATCH", 0)
+	mustInsert(t, st, "2024-07-27 18:00:00", "张三", "MATCH", 1) // 同人同日第二次，不应重复计入 PersonDay
+	mustInsert(t, st, "2024-07-27 08:05:00", "李四", "MATCH", 2)
+	mustInsert(t, st, "2024-07-28 08:00:00", "张三", "MATCH", 3) // 次日，应新增一条 PersonDay
+	mustInsert(t, st, "2024-07-27 09:00:00", "", "ERROR", 4)
+	mustInsert(t, st, "2024-07-27 09:05:00", "UNKNOWN", "MATCH", 5) // UNKNOWN 匹配不算有效签到
+
+	stats, err := st.Stats(StatsRange{
+		From: mustParse(t, "2024-07-27 00:00:00"),
+		To:   mustParse(t, "2024-07-29 00:00:00"),
+	})
+	if err != nil {
+		t.Fatalf("Stats 失败: %v", err)
+	}
+
+	if stats.Total != 6 {
+		t.Errorf("Total = %d, 期望 6", stats.Total)
+	}
+	if stats.Error != 1 {
+		t.Errorf("Error = %d, 期望 1", stats.Error)
+	}
+	if stats.Valid != 3 {
+		t.Errorf("Valid = %d, 期望 3（张三 x2 天 + 李四 x1 天，去重后）", stats.Valid)
+	}
+
+	dayPeople := map[string]int{}
+	for _, dp := range stats.DayPeople {
+		dayPeople[dp.Date] = dp.People
+	}
+	if dayPeople["2024-07-27"] != 2 {
+		t.Errorf("2024-07-27 DayPeople = %d, 期望 2（张三、李四，UNKNOWN 不计入）", dayPeople["2024-07-27"])
+	}
+	if dayPeople["2024-07-28"] != 1 {
+		t.Errorf("2024-07-28 DayPeople = %d, 期望 1", dayPeople["2024-07-28"])
+	}
+}
+
+// TestStats_RangeBoundary 验证 From 为闭区间、To 为开区间（[From, To)），
+// 与 applyFilter 中 ">=" / "<" 的语义保持一致。
+func TestStats_RangeBoundary(t *testing.T) {
+	st := newTestStore(t)
+
+	mustInsert(t, st, "2024-07-27 00:00:00", "张三", "MATCH", 0) // 恰好等于 From，应计入
+	mustInsert(t, st, "2024-07-28 00:00:00", "张三", "MATCH", 1) // 恰好等于 To，应排除
+
+	stats, err := st.Stats(StatsRange{
+		From: mustParse(t, "2024-07-27 00:00:00"),
+		To:   mustParse(t, "2024-07-28 00:00:00"),
+	})
+	if err != nil {
+		t.Fatalf("Stats 失败: %v", err)
+	}
+	if stats.Total != 1 {
+		t.Errorf("Total = %d, 期望 1（To 边界应为开区间）", stats.Total)
+	}
+}
+
+func mustParse(t *testing.T, ts string) time.Time {
+	t.Helper()
+	tm, err := time.Parse("2006-01-02 15:04:05", ts)
+	if err != nil {
+		t.Fatalf("解析时间 %q 失败: %v", ts, err)
+	}
+	return tm
+}
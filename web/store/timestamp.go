@@ -0,0 +1,42 @@
+package store
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// parseTimestamp 尽量兼容 records.csv 中出现过的几种时间格式。
+func parseTimestamp(ts string) (time.Time, error) {
+	ts = strings.TrimSpace(ts)
+	if ts == "" {
+		return time.Time{}, errors.New("empty timestamp")
+	}
+
+	layouts := []string{
+		time.RFC3339,
+		"2006-01-02 15:04:05",
+		"2006/01/02 15:04:05",
+		"2006-01-02 15:04",
+		"2006/01/02 15:04",
+		"2006-01-02",
+		"2006/01/02",
+	}
+
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, ts); err == nil {
+			return t, nil
+		}
+	}
+
+	if i := strings.Index(ts, "."); i != -1 {
+		ts2 := ts[:i]
+		for _, layout := range layouts {
+			if t, err := time.Parse(layout, ts2); err == nil {
+				return t, nil
+			}
+		}
+	}
+
+	return time.Time{}, errors.New("cannot parse timestamp")
+}